@@ -0,0 +1,18 @@
+package main
+
+import "errors"
+
+// Sentinel errors returned by LazyCommit's own logic, as opposed to errors
+// bubbled up from git/jj/llm themselves.
+var (
+	// ErrNoDiff is returned when there is nothing to summarize.
+	ErrNoDiff = errors.New("no changes to summarize")
+
+	// ErrLLMTimeout is returned when the llm command doesn't finish within
+	// the configured command timeout.
+	ErrLLMTimeout = errors.New("llm command timed out")
+
+	// ErrVCSNotFound is returned when neither a Git nor a Jujutsu
+	// repository is detected in the current directory.
+	ErrVCSNotFound = errors.New("neither Git nor Jujutsu repository detected")
+)
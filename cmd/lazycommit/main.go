@@ -2,229 +2,529 @@ package main
 
 import (
 	"bufio"
-	"bytes"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"os/signal"
 	"regexp"
-	"strconv"
 	"strings"
-	"text/template"
+	"time"
 
-	"github.com/BurntSushi/toml"
 	"github.com/tiktoken-go/tokenizer"
+
+	"github.com/stayradiated/lazycommit/config"
+	"github.com/stayradiated/lazycommit/hooks"
+	"github.com/stayradiated/lazycommit/llm"
+	"github.com/stayradiated/lazycommit/oscommands"
+	"github.com/stayradiated/lazycommit/templates"
+	"github.com/stayradiated/lazycommit/vcs"
+	"github.com/stayradiated/lazycommit/vcs/git"
+	"github.com/stayradiated/lazycommit/vcs/jj"
 )
 
-// loadConfig loads configuration from config files and environment variables
-func loadConfig() (AppConfig, error) {
-	// Start with defaults
-	config := DefaultConfig
+// Config holds the configuration for a single run of LazyCommit.
+type Config struct {
+	MaxDiffSize int
+	PromptPath  string
+	UserContext string
+	Backend     vcs.Backend
+	ModelName   string
+	Provider    string
+	BaseURL     string
+	APIKeyEnv   string
+	Template    string
+	Vars        map[string]string
+	Timeout     time.Duration
+	Strategy    string
+	MaxParallel int
+	StdoutOnly  bool
+	Edit        bool
+}
 
-	// Get home directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return config, fmt.Errorf("could not determine home directory: %v", err)
-	}
+// DefaultPromptTemplate is used when no template file and no named template
+// is selected.
+const DefaultPromptTemplate = `You are an expert programmer helping to write concise, informative git commit messages.
+The user will provide you with a git diff, and you will respond with ONLY a commit message.
 
-	// Check for config file in XDG locations
-	xdgConfigHome := os.Getenv("XDG_CONFIG_HOME")
-	if xdgConfigHome == "" {
-		xdgConfigHome = filepath.Join(homeDir, ".config")
+Here are the characteristics of a good commit message:
+- Start with a short summary line (50-72 characters)
+- Use the imperative mood ("Add feature" not "Added feature")
+- Optionally include a more detailed explanatory paragraph after the summary, separated by a blank line
+- Explain WHAT changed and WHY, but not HOW (that's in the diff)
+- Reference relevant issue numbers if applicable (e.g. "Fixes #123")
+
+Current branch: {{.Branch}}
+User context: {{.UserContext}}
+
+Respond with ONLY the commit message, no additional explanations, introductions, or notes.`
+
+// varFlags collects repeated "-v key=value" flags into a map.
+type varFlags map[string]string
+
+func (v varFlags) String() string {
+	return fmt.Sprintf("%v", map[string]string(v))
+}
+
+func (v varFlags) Set(raw string) error {
+	name, value, ok := strings.Cut(raw, "=")
+	if !ok {
+		return fmt.Errorf("invalid -v value %q, expected key=value", raw)
 	}
+	v[name] = value
+	return nil
+}
+
+func main() {
+	vars := varFlags{}
+	templateName := flag.String("t", "", "name of the template to use")
+	flag.Var(vars, "v", "template variable in key=value form (repeatable)")
+
+	var revset string
+	flag.StringVar(&revset, "r", "", "Jujutsu revset to generate a message for (default \"@\")")
+	flag.StringVar(&revset, "revset", "", "Jujutsu revset to generate a message for (default \"@\")")
 
-	// Config file locations to try, in order of precedence
-	configPaths := []string{
-		filepath.Join(xdgConfigHome, "lazycommit", "config.toml"),
-		filepath.Join(homeDir, ".lazycommit.toml"),
+	var rangeArg string
+	flag.StringVar(&rangeArg, "range", "", "Git commit range \"A..B\" to summarize instead of the staged diff")
+
+	strategy := flag.String("strategy", "auto", "diff summarization strategy when the diff exceeds max_diff_tokens: truncate, map-reduce, or auto")
+	maxParallel := flag.Int("max-parallel", 4, "maximum number of file groups to summarize concurrently under the map-reduce strategy")
+
+	stdoutOnly := flag.Bool("stdout-only", false, "print only the generated message to stdout, with no color codes or status messages, for use from a prepare-commit-msg hook")
+	edit := flag.Bool("edit", false, "open $EDITOR on the generated message before printing it")
+
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "templates":
+		if err := runTemplatesCommand(); err != nil {
+			fmt.Printf("\033[0;31mError: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		return
+	case "install-hook", "uninstall-hook":
+		if err := runHookCommand(flag.Arg(0), flag.Args()[1:]); err != nil {
+			fmt.Printf("\033[0;31mError: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Try to load each config file in order
-	var configLoaded bool
-	for _, path := range configPaths {
-		if fileExists(path) {
-			if _, err := toml.DecodeFile(path, &config); err != nil {
-				return config, fmt.Errorf("error loading config from %s: %v", path, err)
-			}
-			fmt.Fprintf(os.Stderr, "Loaded configuration from %s\n", path)
-			configLoaded = true
-			break
+	// Load configuration from file and environment
+	appConfig, err := config.Load()
+	if err != nil {
+		if !*stdoutOnly {
+			fmt.Printf("\033[0;31mWarning: Error loading configuration: %v. Using defaults.\033[0m\n", err)
 		}
+		appConfig = config.Default
 	}
 
-	if !configLoaded {
-		fmt.Fprintf(os.Stderr, "No configuration file found, using defaults\n")
+	// The "cli" provider (the default) shells out to the external `llm`
+	// command; native providers talk to an HTTP API directly instead.
+	if (appConfig.Provider == "" || appConfig.Provider == "cli") && !commandExists("llm") {
+		printError(*stdoutOnly, errors.New("'llm' command is not installed. Please install it and try again."))
+		os.Exit(1)
 	}
 
-	// Override with environment variables if set
-	if envMaxTokens := os.Getenv("LAZYCOMMIT_MAX_TOKENS"); envMaxTokens != "" {
-		if maxTokens, err := strconv.Atoi(envMaxTokens); err == nil {
-			config.MaxDiffTokens = maxTokens
-			fmt.Fprintf(os.Stderr, "Using max tokens from environment: %d\n", maxTokens)
-		}
+	timeout := time.Duration(appConfig.CommandTimeoutSeconds) * time.Second
+
+	backend, err := detectBackend(rangeArg, revset, timeout)
+	if err != nil {
+		printError(*stdoutOnly, err)
+		os.Exit(1)
 	}
 
-	if envPromptPath := os.Getenv("LAZYCOMMIT_TEMPLATE"); envPromptPath != "" {
-		config.PromptPath = envPromptPath
-		fmt.Fprintf(os.Stderr, "Using template path from environment: %s\n", envPromptPath)
+	// Get user context from the first non-flag argument
+	userContext := flag.Arg(0)
+
+	// Create config for commit message generation
+	cfg := Config{
+		MaxDiffSize: appConfig.MaxDiffTokens,
+		PromptPath:  appConfig.PromptPath,
+		UserContext: userContext,
+		Backend:     backend,
+		ModelName:   appConfig.ModelName,
+		Provider:    appConfig.Provider,
+		BaseURL:     appConfig.BaseURL,
+		APIKeyEnv:   appConfig.APIKeyEnv,
+		Template:    *templateName,
+		Vars:        vars,
+		Timeout:     timeout,
+		Strategy:    *strategy,
+		MaxParallel: *maxParallel,
+		StdoutOnly:  *stdoutOnly,
+		Edit:        *edit,
 	}
 
-	if envModelName := os.Getenv("LAZYCOMMIT_MODEL"); envModelName != "" {
-		config.ModelName = envModelName
-		fmt.Fprintf(os.Stderr, "Using model from environment: %s\n", envModelName)
+	if !cfg.StdoutOnly {
+		fmt.Fprintf(os.Stderr, "Using %s for version control\n", backendName(backend))
 	}
 
-	return config, nil
-}
+	// Let Ctrl-C cancel a hung llm call (or git/jj call) cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
+	err = generateCommitMessage(ctx, cfg)
+	if err != nil {
+		printError(cfg.StdoutOnly, err)
+		os.Exit(1)
+	}
+}
 
-// Config holds the configuration for LazyCommit
-type Config struct {
-	MaxDiffSize int
-	PromptPath  string
-	UserContext string
-	IsGit       bool // true for git, false for jj
-	ModelName   string
+// printError reports a fatal error to the user. With --stdout-only it goes
+// to stderr, uncolored, so stdout stays clean for a hook capturing the
+// generated commit message.
+func printError(stdoutOnly bool, err error) {
+	if stdoutOnly {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	fmt.Printf("\033[0;31mError: %v\033[0m\n", err)
 }
 
-// AppConfig holds the configuration loaded from the config file or environment
-type AppConfig struct {
-	MaxDiffTokens int    `toml:"max_diff_tokens"`
-	PromptPath    string `toml:"prompt_path"`
-	ModelName     string `toml:"model_name"`
+// detectBackend picks the vcs.Backend for the current directory, preferring
+// Jujutsu when both a Git and a Jujutsu repository are detected.
+func detectBackend(rangeArg, revset string, timeout time.Duration) (vcs.Backend, error) {
+	gitBackend := git.New(rangeArg, timeout)
+	jjBackend := jj.New(revset, timeout)
+
+	isGit := gitBackend.Detect()
+	isJJ := jjBackend.Detect()
+
+	if !isGit && !isJJ {
+		return nil, ErrVCSNotFound
+	}
+
+	if isGit && !isJJ {
+		return gitBackend, nil
+	}
+
+	return jjBackend, nil
 }
 
-// DefaultConfig provides default values for the application
-var DefaultConfig = AppConfig{
-	MaxDiffTokens: 12500,
-	PromptPath:    "",
-	ModelName:     "",
+// backendName returns a human-readable name for a vcs.Backend, for status
+// messages.
+func backendName(backend vcs.Backend) string {
+	switch backend.(type) {
+	case *git.Backend:
+		return "Git"
+	case *jj.Backend:
+		return "Jujutsu"
+	default:
+		return "unknown"
+	}
 }
 
-// PromptData holds the data for rendering the prompt template
-type PromptData struct {
-	Branch      string
-	UserContext string
+// runHookCommand implements `lazycommit install-hook` and
+// `lazycommit uninstall-hook`: it wires lazycommit into Git's
+// prepare-commit-msg hook and, for Jujutsu, a `jj describe-ai` alias.
+func runHookCommand(cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	global := fs.Bool("global", false, "install into the global hooks location instead of the current repository")
+	force := fs.Bool("force", false, "overwrite an existing hook that isn't already lazycommit's own")
+	chain := fs.Bool("chain", false, "append to an existing hook instead of replacing it (uninstall always keeps the rest of a chained hook)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts := hooks.Options{Global: *global, Force: *force, Chain: *chain}
+
+	isGit := git.New("", 0).Detect()
+	isJJ := jj.New("", 0).Detect()
+	if !isGit && !isJJ {
+		return ErrVCSNotFound
+	}
+
+	if cmd == "install-hook" {
+		return installHooks(opts, isGit, isJJ)
+	}
+	return uninstallHooks(opts, isGit, isJJ)
 }
 
-// DefaultPromptTemplate is used when no template file is found
-const DefaultPromptTemplate = `You are an expert programmer helping to write concise, informative git commit messages. 
-The user will provide you with a git diff, and you will respond with ONLY a commit message.
+func installHooks(opts hooks.Options, isGit, isJJ bool) error {
+	if isGit {
+		path, err := hooks.InstallGit(opts)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Installed Git hook at %s\n", path)
+	}
 
-Here are the characteristics of a good commit message:
-- Start with a short summary line (50-72 characters)
-- Use the imperative mood ("Add feature" not "Added feature")
-- Optionally include a more detailed explanatory paragraph after the summary, separated by a blank line
-- Explain WHAT changed and WHY, but not HOW (that's in the diff)
-- Reference relevant issue numbers if applicable (e.g. "Fixes #123")
+	if isJJ {
+		if err := hooks.InstallJJ(opts); err != nil {
+			return err
+		}
+		fmt.Println("Installed jj alias: run `jj describe-ai` to describe the working copy with a generated message")
+	}
 
-Current branch: {{.Branch}}
-User context: {{.UserContext}}
+	return nil
+}
 
-Respond with ONLY the commit message, no additional explanations, introductions, or notes.`
+func uninstallHooks(opts hooks.Options, isGit, isJJ bool) error {
+	var removed bool
 
-func main() {
-	// Check if llm command is installed
-	if !commandExists("llm") {
-		fmt.Printf("\033[0;31mError: 'llm' command is not installed. Please install it and try again.\033[0m\n")
-		os.Exit(1)
+	if isGit {
+		switch err := hooks.UninstallGit(opts); {
+		case err == nil:
+			removed = true
+			fmt.Println("Removed Git hook")
+		case !errors.Is(err, hooks.ErrNotInstalled):
+			return err
+		}
 	}
 
-	// Determine if we're in a git or jj repository
-	isGit := isGitRepo()
-	isJJ := isJJRepo()
+	if isJJ {
+		switch err := hooks.UninstallJJ(opts); {
+		case err == nil:
+			removed = true
+			fmt.Println("Removed jj alias")
+		case !errors.Is(err, hooks.ErrNotInstalled):
+			return err
+		}
+	}
 
-	if !isGit && !isJJ {
-		fmt.Printf("\033[0;31mError: Neither Git nor Jujutsu repository detected.\033[0m\n")
-		os.Exit(1)
+	if !removed {
+		return hooks.ErrNotInstalled
 	}
-	
-	// Prefer Jujutsu if both are available
-	useGit := isGit && !isJJ
+	return nil
+}
 
-	// Get user context from command line arguments
-	userContext := ""
-	if len(os.Args) > 1 {
-		userContext = os.Args[1]
+// runTemplatesCommand implements `lazycommit templates`: it lists every
+// available template, merging global and repo scopes (repo wins), along
+// with each template's variables.
+func runTemplatesCommand() error {
+	lib, err := loadTemplateLibrary()
+	if err != nil {
+		return err
 	}
 
-	// Load configuration from file and environment
-	appConfig, err := loadConfig()
+	names := lib.Names()
+	if len(names) == 0 {
+		fmt.Println("No templates found.")
+		return nil
+	}
+
+	for _, name := range names {
+		t, _ := lib.Get(name)
+		fmt.Printf("%s\n", t.Name)
+		if t.Description != "" {
+			fmt.Printf("  %s\n", t.Description)
+		}
+		for _, v := range t.Variables {
+			fmt.Printf("  - %s", v.Name)
+			if v.Required {
+				fmt.Printf(" (required)")
+			}
+			if v.Default != "" {
+				fmt.Printf(" (default: %s)", v.Default)
+			}
+			if v.Description != "" {
+				fmt.Printf(": %s", v.Description)
+			}
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+// loadTemplateLibrary loads the global template library and merges in the
+// repo-scoped library (repo wins). The repo-scoped library is looked up under
+// the current working directory; parent directories are not searched.
+func loadTemplateLibrary() (templates.Library, error) {
+	configDir, err := config.ConfigDir()
 	if err != nil {
-		fmt.Printf("\033[0;31mWarning: Error loading configuration: %v. Using defaults.\033[0m\n", err)
-		appConfig = DefaultConfig
+		return templates.Library{}, err
 	}
 
-	// Create config for commit message generation
-	config := Config{
-		MaxDiffSize: appConfig.MaxDiffTokens,
-		PromptPath:  appConfig.PromptPath,
-		UserContext: userContext,
-		IsGit:       useGit,
-		ModelName:   appConfig.ModelName,
+	global, err := templates.LoadGlobal(configDir)
+	if err != nil {
+		return templates.Library{}, err
 	}
 
-	// Generate and display commit message
-	if useGit {
-		fmt.Fprintf(os.Stderr, "Using Git for version control\n")
-	} else {
-		fmt.Fprintf(os.Stderr, "Using Jujutsu for version control\n")
+	repoRoot, err := os.Getwd()
+	if err != nil {
+		return global, nil
 	}
-	
-	err = generateCommitMessage(config)
+
+	repo, err := templates.LoadRepo(repoRoot)
 	if err != nil {
-		fmt.Printf("\033[0;31mError: %v\033[0m\n", err)
-		os.Exit(1)
+		return templates.Library{}, err
 	}
+
+	return templates.Merge(global, repo), nil
 }
 
 // generateCommitMessage generates a commit message using the configured VCS and LLM
-func generateCommitMessage(config Config) error {
-	// Get the current branch name
-	branch := getBranchName(config.IsGit)
-	
-	// Render the prompt template
-	promptData := PromptData{
-		Branch:      branch,
-		UserContext: config.UserContext,
+func generateCommitMessage(ctx context.Context, cfg Config) error {
+	// Get the current branch or change
+	branch, err := cfg.Backend.BranchOrChange(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to determine current branch or change: %v", err)
 	}
-	
-	prompt, err := renderPromptTemplate(config.PromptPath, promptData)
+
+	prompt, err := renderPrompt(cfg, branch)
 	if err != nil {
 		return fmt.Errorf("failed to render prompt template: %v", err)
 	}
 
 	// Get diff with excluded generated files
-	diff, err := getDiff(config)
+	diff, err := getDiff(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to get diff: %v", err)
 	}
 
-	// Truncate diff if it's too long using tiktoken
-	truncatedDiff, err := truncateDiff(diff, config.MaxDiffSize)
+	if strings.TrimSpace(diff) == "" {
+		return ErrNoDiff
+	}
+
+	// Shrink the diff to fit the token budget, either by truncating it or,
+	// for a strategy of "map-reduce" (or "auto" on a very large diff), by
+	// summarizing it in per-file groups first.
+	summarizedDiff, err := summarizeForPrompt(ctx, cfg, diff)
+	if err != nil {
+		// Fall back to raw diff if summarization fails
+		fmt.Fprintf(os.Stderr, "Warning: Failed to summarize diff: %v. Using raw diff.\n", err)
+		summarizedDiff = diff
+	}
+
+	// Generate commit message with the configured LLM provider. With
+	// --edit the full message is needed before it can be handed to
+	// $EDITOR, so streaming to the terminal is disabled; otherwise it
+	// streams straight to stdout as it arrives.
+	if cfg.ModelName != "" {
+		fmt.Fprintf(os.Stderr, "Using model: %s\n", cfg.ModelName)
+	} else {
+		fmt.Fprintf(os.Stderr, "Using default model\n")
+	}
+
+	var stream io.Writer
+	if !cfg.Edit {
+		stream = os.Stdout
+	}
+
+	provider, err := llm.New(llm.Config{
+		Name:      cfg.Provider,
+		Model:     cfg.ModelName,
+		BaseURL:   cfg.BaseURL,
+		APIKeyEnv: cfg.APIKeyEnv,
+		Timeout:   cfg.Timeout,
+		Stream:    stream,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to configure llm provider: %v", err)
+	}
+
+	message, err := provider.Complete(ctx, prompt, summarizedDiff)
+	if errors.Is(err, oscommands.ErrTimeout) {
+		return ErrLLMTimeout
+	}
 	if err != nil {
-		// Fall back to raw diff if tokenization fails
-		fmt.Fprintf(os.Stderr, "Warning: Failed to tokenize diff: %v. Using raw diff.\n", err)
-		truncatedDiff = diff
+		return err
+	}
+
+	if !cfg.Edit {
+		return nil
+	}
+
+	edited, err := editMessage(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to edit message: %v", err)
+	}
+	fmt.Print(edited)
+	return nil
+}
+
+// editMessage writes message to a temporary file, opens it in $EDITOR (or
+// $VISUAL, falling back to vi), and returns the file's contents afterward.
+// Unlike the commands LazyCommit runs on its own, this one is interactive
+// and isn't bounded by the configured command timeout.
+func editMessage(ctx context.Context, message string) (string, error) {
+	tmp, err := os.CreateTemp("", "lazycommit-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(message); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temp file: %v", err)
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	err = oscommands.NewCmdBuilder(editor).
+		AddDynamicArguments(tmp.Name()).
+		RunWithContextStreaming(ctx, os.Stdin, os.Stdout, os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", editor, err)
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited message: %v", err)
+	}
+
+	return string(edited), nil
+}
+
+// renderPrompt builds the system prompt, either from the named template
+// (-t), the configured PromptPath, or the default embedded template.
+func renderPrompt(cfg Config, branch string) (string, error) {
+	if cfg.Template != "" {
+		lib, err := loadTemplateLibrary()
+		if err != nil {
+			return "", err
+		}
+
+		t, ok := lib.Get(cfg.Template)
+		if !ok {
+			return "", fmt.Errorf("no such template: %q (run `lazycommit templates` to list available templates)", cfg.Template)
+		}
+
+		data, err := t.RenderData(branch, cfg.UserContext, cfg.Vars)
+		if err != nil {
+			return "", err
+		}
+
+		return t.Render(data)
 	}
 
-	// Generate commit message with LLM
-	var llmCmd *exec.Cmd
-	if config.ModelName != "" {
-		// Use specified model if provided
-		llmCmd = exec.Command("llm", "-m", config.ModelName, "-s", prompt)
-		fmt.Fprintf(os.Stderr, "Using model: %s\n", config.ModelName)
+	return renderPromptTemplate(cfg.PromptPath, templates.Data{
+		Branch:      branch,
+		UserContext: cfg.UserContext,
+		Vars:        cfg.Vars,
+	})
+}
+
+// renderPromptTemplate renders the prompt template with the given data
+func renderPromptTemplate(templatePath string, data templates.Data) (string, error) {
+	tmpl := templates.Template{Name: "default", System: DefaultPromptTemplate}
+
+	if templatePath != "" && fileExists(templatePath) {
+		content, err := os.ReadFile(templatePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template file: %v", err)
+		}
+		tmpl.System = string(content)
+		fmt.Fprintf(os.Stderr, "Using template from: %s\n", templatePath)
 	} else {
-		// Otherwise let llm use its default model
-		llmCmd = exec.Command("llm", "-s", prompt)
-		fmt.Fprintf(os.Stderr, "Using default llm model\n")
+		fmt.Fprintf(os.Stderr, "Using default embedded template\n")
 	}
-	
-	llmCmd.Stdin = strings.NewReader(truncatedDiff)
-	llmCmd.Stdout = os.Stdout
-	llmCmd.Stderr = os.Stderr
 
-	return llmCmd.Run()
+	return tmpl.Render(data)
 }
 
 // truncateDiff truncates the diff to a specified number of tokens
@@ -261,41 +561,9 @@ func truncateDiff(diff string, maxTokens int) (string, error) {
 	return truncatedDiff, nil
 }
 
-// renderPromptTemplate renders the prompt template with the given data
-func renderPromptTemplate(templatePath string, data PromptData) (string, error) {
-	var tmplContent string
-	
-	// Try to read the template file if path is provided
-	if templatePath != "" && fileExists(templatePath) {
-		content, err := os.ReadFile(templatePath)
-		if err != nil {
-			return "", fmt.Errorf("failed to read template file: %v", err)
-		}
-		tmplContent = string(content)
-		fmt.Fprintf(os.Stderr, "Using template from: %s\n", templatePath)
-	} else {
-		// Use the default template if file doesn't exist or no path provided
-		tmplContent = DefaultPromptTemplate
-		fmt.Fprintf(os.Stderr, "Using default embedded template\n")
-	}
-	
-	// Parse the template
-	tmpl, err := template.New("prompt").Parse(tmplContent)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %v", err)
-	}
-	
-	// Execute the template
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %v", err)
-	}
-	
-	return buf.String(), nil
-}
-
-// getDiff gets the diff from the VCS, excluding generated files
-func getDiff(config Config) (string, error) {
+// getDiff gets the diff from the configured VCS backend, excluding
+// generated files.
+func getDiff(ctx context.Context, cfg Config) (string, error) {
 	excludePatterns := []string{}
 
 	// Always exclude common lock files
@@ -306,7 +574,7 @@ func getDiff(config Config) (string, error) {
 	}
 
 	// Add generated files from .gitattributes if using Git
-	if config.IsGit && fileExists(".gitattributes") {
+	if _, isGit := cfg.Backend.(*git.Backend); isGit && fileExists(".gitattributes") {
 		generatedPatterns, err := getGeneratedFilesFromGitattributes()
 		if err != nil {
 			return "", fmt.Errorf("failed to parse .gitattributes: %v", err)
@@ -317,28 +585,7 @@ func getDiff(config Config) (string, error) {
 	// Add common excludes
 	excludePatterns = append(excludePatterns, commonExcludes...)
 
-	// Get diff based on VCS
-	var cmd *exec.Cmd
-	if config.IsGit {
-		args := []string{"diff", "--cached", "--", "."}
-		for _, pattern := range excludePatterns {
-			args = append(args, fmt.Sprintf(":(exclude)%s", pattern))
-		}
-		cmd = exec.Command("git", args...)
-	} else {
-		args := []string{"diff", "--git"}
-		for _, pattern := range excludePatterns {
-			args = append(args, fmt.Sprintf("~%s", pattern))
-		}
-		cmd = exec.Command("jj", args...)
-	}
-
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get diff: %v", err)
-	}
-
-	return string(output), nil
+	return cfg.Backend.Diff(ctx, vcs.DiffOptions{ExcludePatterns: excludePatterns})
 }
 
 // getGeneratedFilesFromGitattributes parses .gitattributes to find generated files
@@ -368,42 +615,6 @@ func getGeneratedFilesFromGitattributes() ([]string, error) {
 	return patterns, nil
 }
 
-// getBranchName gets the current branch name from Git or Jujutsu
-func getBranchName(isGit bool) string {
-	var cmd *exec.Cmd
-	if isGit {
-		cmd = exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	} else {
-		cmd = exec.Command("jj", "log", "--no-graph", "-T", "local_bookmarks", "--limit", "1")
-	}
-
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-
-	return strings.TrimSpace(string(output))
-}
-
-// isGitRepo checks if the current directory is a Git repository
-func isGitRepo() bool {
-	_, err := os.Stat(".git")
-	if err == nil {
-		return true
-	}
-
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
-	err = cmd.Run()
-	return err == nil
-}
-
-// isJJRepo checks if the current directory is a Jujutsu repository
-func isJJRepo() bool {
-	cmd := exec.Command("jj", "status", "--quiet")
-	err := cmd.Run()
-	return err == nil
-}
-
 // commandExists checks if a command exists
 func commandExists(cmd string) bool {
 	_, err := exec.LookPath(cmd)
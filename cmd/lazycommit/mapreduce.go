@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tiktoken-go/tokenizer"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/stayradiated/lazycommit/diffsplit"
+	"github.com/stayradiated/lazycommit/llm"
+)
+
+// reservedPromptTokens is subtracted from MaxDiffSize when packing
+// map-reduce groups, to leave room for the summarization system prompt
+// itself and its response.
+const reservedPromptTokens = 500
+
+// summarizeSystemPrompt is the system prompt used for each per-group
+// summarization call in the map-reduce strategy.
+const summarizeSystemPrompt = `You are an expert programmer. Summarize the following file changes in 1-3 short bullet points each. Be specific about what changed; note why only if it's evident from the diff itself. Respond with ONLY the bullet points.`
+
+// summarizeForPrompt returns the diff content to send to the final LLM
+// call, shrunk to fit cfg.MaxDiffSize according to cfg.Strategy.
+func summarizeForPrompt(ctx context.Context, cfg Config, diff string) (string, error) {
+	enc, err := tokenizer.Get(tokenizer.Cl100kBase)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tokenizer: %v", err)
+	}
+
+	tokens, _, err := enc.Encode(diff)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode diff: %v", err)
+	}
+
+	if len(tokens) <= cfg.MaxDiffSize {
+		return diff, nil
+	}
+
+	strategy := chooseStrategy(cfg.Strategy, len(tokens), cfg.MaxDiffSize)
+	if strategy == "truncate" {
+		return truncateDiff(diff, cfg.MaxDiffSize)
+	}
+
+	return summarizeByMapReduce(ctx, cfg, enc, diff)
+}
+
+// chooseStrategy resolves "auto" to a concrete strategy: map-reduce once
+// the diff is more than twice the token budget, truncate otherwise.
+func chooseStrategy(strategy string, tokens, maxTokens int) string {
+	if strategy != "auto" {
+		return strategy
+	}
+	if tokens > 2*maxTokens {
+		return "map-reduce"
+	}
+	return "truncate"
+}
+
+// summarizeByMapReduce splits diff into per-file chunks, packs them into
+// token-budgeted groups, summarizes each group with its own llm call (run
+// with up to cfg.MaxParallel at a time), and returns the concatenated
+// summaries.
+func summarizeByMapReduce(ctx context.Context, cfg Config, enc tokenizer.Codec, diff string) (string, error) {
+	budget := cfg.MaxDiffSize - reservedPromptTokens
+	if budget <= 0 {
+		budget = cfg.MaxDiffSize
+	}
+
+	groups, err := packGroups(enc, diffsplit.SplitByFile(diff), budget)
+	if err != nil {
+		return "", err
+	}
+
+	maxParallel := cfg.MaxParallel
+	if maxParallel <= 0 {
+		maxParallel = 1
+	}
+
+	summaries := make([]string, len(groups))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxParallel)
+
+	for i, group := range groups {
+		i, group := i, group
+		g.Go(func() error {
+			summary, err := summarizeGroup(gctx, cfg, group)
+			if err != nil {
+				return fmt.Errorf("failed to summarize group %d: %w", i, err)
+			}
+			summaries[i] = summary
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return "", err
+	}
+
+	return strings.Join(summaries, "\n\n"), nil
+}
+
+// packGroups greedily packs file chunks into groups of at most budget
+// tokens. A chunk that alone exceeds budget is further split on hunk
+// boundaries; if even its header alone exceeds the budget, a warning is
+// emitted and the chunk is kept whole so the summary stays grounded.
+func packGroups(enc tokenizer.Codec, chunks []diffsplit.Chunk, budget int) ([]string, error) {
+	var flat []diffsplit.Chunk
+	for _, chunk := range chunks {
+		size, err := tokenCount(enc, chunk.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if size <= budget {
+			flat = append(flat, chunk)
+			continue
+		}
+
+		hunkSize, err := tokenCount(enc, headerOnly(chunk))
+		if err != nil {
+			return nil, err
+		}
+		if hunkSize > budget {
+			fmt.Fprintf(os.Stderr, "Warning: %s's diff header alone exceeds the token budget; summarizing it whole\n", chunk.Path)
+			flat = append(flat, chunk)
+			continue
+		}
+
+		flat = append(flat, diffsplit.SplitHunks(chunk)...)
+	}
+
+	var groups []string
+	var current strings.Builder
+	currentTokens := 0
+
+	for _, chunk := range flat {
+		size, err := tokenCount(enc, chunk.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if current.Len() > 0 && currentTokens+size > budget {
+			groups = append(groups, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+
+		current.WriteString(chunk.Body)
+		current.WriteString("\n")
+		currentTokens += size
+	}
+
+	if current.Len() > 0 {
+		groups = append(groups, current.String())
+	}
+
+	return groups, nil
+}
+
+// headerOnly returns just the first line of a chunk's body, to measure
+// whether its header alone would blow the token budget.
+func headerOnly(chunk diffsplit.Chunk) string {
+	if idx := strings.IndexByte(chunk.Body, '\n'); idx >= 0 {
+		return chunk.Body[:idx]
+	}
+	return chunk.Body
+}
+
+func tokenCount(enc tokenizer.Codec, text string) (int, error) {
+	tokens, _, err := enc.Encode(text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode diff chunk: %v", err)
+	}
+	return len(tokens), nil
+}
+
+// summarizeGroup invokes the configured LLM provider once to summarize a
+// single token-budgeted group of file changes.
+func summarizeGroup(ctx context.Context, cfg Config, group string) (string, error) {
+	provider, err := llm.New(llm.Config{
+		Name:      cfg.Provider,
+		Model:     cfg.ModelName,
+		BaseURL:   cfg.BaseURL,
+		APIKeyEnv: cfg.APIKeyEnv,
+		Timeout:   cfg.Timeout,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to configure llm provider: %v", err)
+	}
+
+	summary, err := provider.Complete(ctx, summarizeSystemPrompt, group)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(summary), nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tiktoken-go/tokenizer"
+
+	"github.com/stayradiated/lazycommit/diffsplit"
+)
+
+func TestChooseStrategy(t *testing.T) {
+	if got := chooseStrategy("truncate", 1000, 10); got != "truncate" {
+		t.Errorf("got %q, want explicit strategy to pass through unchanged", got)
+	}
+	if got := chooseStrategy("auto", 101, 50); got != "map-reduce" {
+		t.Errorf("got %q, want %q for a diff more than twice the budget", got, "map-reduce")
+	}
+	if got := chooseStrategy("auto", 60, 50); got != "truncate" {
+		t.Errorf("got %q, want %q for a diff under twice the budget", got, "truncate")
+	}
+}
+
+func TestPackGroupsPacksSmallChunksTogether(t *testing.T) {
+	enc, err := tokenizer.Get(tokenizer.Cl100kBase)
+	if err != nil {
+		t.Fatalf("failed to get tokenizer: %v", err)
+	}
+
+	chunks := []diffsplit.Chunk{
+		{Path: "a.go", Body: "diff a"},
+		{Path: "b.go", Body: "diff b"},
+	}
+
+	groups, err := packGroups(enc, chunks, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want 1", len(groups))
+	}
+	if !strings.Contains(groups[0], "diff a") || !strings.Contains(groups[0], "diff b") {
+		t.Errorf("expected both chunks packed into one group, got:\n%s", groups[0])
+	}
+}
+
+func TestPackGroupsStartsNewGroupWhenOverBudget(t *testing.T) {
+	enc, err := tokenizer.Get(tokenizer.Cl100kBase)
+	if err != nil {
+		t.Fatalf("failed to get tokenizer: %v", err)
+	}
+
+	chunks := []diffsplit.Chunk{
+		{Path: "a.go", Body: "diff a"},
+		{Path: "b.go", Body: "diff b"},
+	}
+
+	size, err := tokenCount(enc, "diff a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	groups, err := packGroups(enc, chunks, size)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2 (one chunk per group once the budget is exhausted)", len(groups))
+	}
+}
+
+func TestPackGroupsSplitsOversizedChunkOnHunks(t *testing.T) {
+	enc, err := tokenizer.Get(tokenizer.Cl100kBase)
+	if err != nil {
+		t.Fatalf("failed to get tokenizer: %v", err)
+	}
+
+	chunk := diffsplit.Chunk{
+		Path: "big.go",
+		Body: "diff --git a/big.go b/big.go\n" +
+			"@@ -1,1 +1,1 @@\n-old one\n+new one\n" +
+			"@@ -10,1 +10,1 @@\n-old two\n+new two\n",
+	}
+
+	hunkSize, err := tokenCount(enc, diffsplit.SplitHunks(chunk)[0].Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	groups, err := packGroups(enc, []diffsplit.Chunk{chunk}, hunkSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2 (one per hunk, since the whole chunk exceeds the budget)", len(groups))
+	}
+}
+
+func TestSummarizeByMapReduceJoinsPerGroupSummaries(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":\"summary %d\"}}]}\n\n", calls)
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	enc, err := tokenizer.Get(tokenizer.Cl100kBase)
+	if err != nil {
+		t.Fatalf("failed to get tokenizer: %v", err)
+	}
+
+	diff := "diff --git a/a.go b/a.go\n@@ -1,1 +1,1 @@\n-old a\n+new a\n" +
+		"diff --git a/b.go b/b.go\n@@ -1,1 +1,1 @@\n-old b\n+new b\n"
+
+	size, err := tokenCount(enc, "diff --git a/a.go b/a.go\n@@ -1,1 +1,1 @@\n-old a\n+new a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := Config{
+		Provider:    "openai",
+		BaseURL:     server.URL,
+		MaxDiffSize: size,
+		MaxParallel: 1,
+	}
+
+	got, err := summarizeByMapReduce(context.Background(), cfg, enc, diff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("got %d llm calls, want 2 (one per group)", calls)
+	}
+	if !strings.Contains(got, "summary 1") || !strings.Contains(got, "summary 2") {
+		t.Errorf("expected both group summaries joined, got %q", got)
+	}
+}
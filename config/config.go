@@ -0,0 +1,129 @@
+// Package config loads and merges LazyCommit's configuration from the
+// environment and from TOML files on disk.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+)
+
+// AppConfig holds the configuration loaded from the config file or environment.
+type AppConfig struct {
+	MaxDiffTokens         int    `toml:"max_diff_tokens"`
+	PromptPath            string `toml:"prompt_path"`
+	ModelName             string `toml:"model_name"`
+	CommandTimeoutSeconds int    `toml:"command_timeout_seconds"`
+
+	// Provider selects how commit messages are generated: "cli" (default,
+	// shells out to the `llm` command) or one of the native adapters
+	// ("openai", "anthropic", "ollama", "openrouter", "llamacpp").
+	Provider string `toml:"provider"`
+
+	// BaseURL overrides a native provider's default API base URL. Unused
+	// by "cli".
+	BaseURL string `toml:"base_url"`
+
+	// APIKeyEnv is the name of the environment variable holding a native
+	// provider's API key. Unused by "cli".
+	APIKeyEnv string `toml:"api_key_env"`
+}
+
+// Default provides default values for the application.
+var Default = AppConfig{
+	MaxDiffTokens:         12500,
+	PromptPath:            "",
+	ModelName:             "",
+	CommandTimeoutSeconds: 60,
+	Provider:              "cli",
+	BaseURL:               "",
+	APIKeyEnv:             "",
+}
+
+// ConfigDir returns the directory that holds LazyCommit's global
+// configuration, honoring XDG_CONFIG_HOME when it is set.
+func ConfigDir() (string, error) {
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		return filepath.Join(xdgConfigHome, "lazycommit"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %v", err)
+	}
+
+	return filepath.Join(homeDir, ".config", "lazycommit"), nil
+}
+
+// Load loads configuration from config files and environment variables.
+func Load() (AppConfig, error) {
+	// Start with defaults
+	cfg := Default
+
+	configDir, err := ConfigDir()
+	if err != nil {
+		return cfg, err
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return cfg, fmt.Errorf("could not determine home directory: %v", err)
+	}
+
+	// Config file locations to try, in order of precedence
+	configPaths := []string{
+		filepath.Join(configDir, "config.toml"),
+		filepath.Join(homeDir, ".lazycommit.toml"),
+	}
+
+	// Try to load each config file in order
+	var configLoaded bool
+	for _, path := range configPaths {
+		if fileExists(path) {
+			if _, err := toml.DecodeFile(path, &cfg); err != nil {
+				return cfg, fmt.Errorf("error loading config from %s: %v", path, err)
+			}
+			fmt.Fprintf(os.Stderr, "Loaded configuration from %s\n", path)
+			configLoaded = true
+			break
+		}
+	}
+
+	if !configLoaded {
+		fmt.Fprintf(os.Stderr, "No configuration file found, using defaults\n")
+	}
+
+	// Override with environment variables if set
+	if envMaxTokens := os.Getenv("LAZYCOMMIT_MAX_TOKENS"); envMaxTokens != "" {
+		if maxTokens, err := strconv.Atoi(envMaxTokens); err == nil {
+			cfg.MaxDiffTokens = maxTokens
+			fmt.Fprintf(os.Stderr, "Using max tokens from environment: %d\n", maxTokens)
+		}
+	}
+
+	if envPromptPath := os.Getenv("LAZYCOMMIT_TEMPLATE"); envPromptPath != "" {
+		cfg.PromptPath = envPromptPath
+		fmt.Fprintf(os.Stderr, "Using template path from environment: %s\n", envPromptPath)
+	}
+
+	if envModelName := os.Getenv("LAZYCOMMIT_MODEL"); envModelName != "" {
+		cfg.ModelName = envModelName
+		fmt.Fprintf(os.Stderr, "Using model from environment: %s\n", envModelName)
+	}
+
+	if envProvider := os.Getenv("LAZYCOMMIT_PROVIDER"); envProvider != "" {
+		cfg.Provider = envProvider
+		fmt.Fprintf(os.Stderr, "Using provider from environment: %s\n", envProvider)
+	}
+
+	return cfg, nil
+}
+
+// fileExists checks if a file exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
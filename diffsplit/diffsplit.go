@@ -0,0 +1,108 @@
+// Package diffsplit splits a unified diff into smaller, independently
+// summarizable chunks: first on file boundaries, and, for a file too large
+// to fit in a single token budget, further on hunk boundaries.
+package diffsplit
+
+import "strings"
+
+// fileHeaderPrefix marks the start of a new file's diff in a unified diff
+// produced by `git diff` or `jj diff --git`.
+const fileHeaderPrefix = "diff --git "
+
+// hunkHeaderPrefix marks the start of a hunk within a file's diff.
+const hunkHeaderPrefix = "@@"
+
+// Chunk is a self-contained slice of a unified diff.
+type Chunk struct {
+	// Path is the file the chunk belongs to, taken from its "diff --git"
+	// header, so summaries stay grounded even after the chunk is split
+	// further.
+	Path string
+
+	// Body is the chunk's diff text, including its "diff --git" header
+	// (and, for a hunk chunk, that header repeated above the hunk).
+	Body string
+}
+
+// SplitByFile splits a unified diff on "diff --git a/... b/..." headers,
+// returning one Chunk per file.
+func SplitByFile(diff string) []Chunk {
+	lines := strings.Split(diff, "\n")
+
+	var chunks []Chunk
+	var current []string
+	var path string
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		chunks = append(chunks, Chunk{Path: path, Body: strings.Join(current, "\n")})
+		current = nil
+	}
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, fileHeaderPrefix) {
+			flush()
+			path = filePathFromHeader(line)
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return chunks
+}
+
+// SplitHunks further splits a single file Chunk on "@@" hunk boundaries,
+// repeating the file's "diff --git" header (and any preceding index/mode
+// lines) above each resulting hunk so every piece stays self-describing.
+func SplitHunks(chunk Chunk) []Chunk {
+	lines := strings.Split(chunk.Body, "\n")
+
+	var preamble []string
+	var hunks [][]string
+	var current []string
+	inHunk := false
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, hunkHeaderPrefix) {
+			if current != nil {
+				hunks = append(hunks, current)
+			}
+			current = []string{line}
+			inHunk = true
+			continue
+		}
+		if inHunk {
+			current = append(current, line)
+		} else {
+			preamble = append(preamble, line)
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, current)
+	}
+
+	if len(hunks) == 0 {
+		return []Chunk{chunk}
+	}
+
+	result := make([]Chunk, 0, len(hunks))
+	for _, hunk := range hunks {
+		body := strings.Join(preamble, "\n") + "\n" + strings.Join(hunk, "\n")
+		result = append(result, Chunk{Path: chunk.Path, Body: body})
+	}
+
+	return result
+}
+
+// filePathFromHeader extracts the "b/..." path from a "diff --git a/... b/..."
+// header line, falling back to the whole line if it doesn't match the
+// expected shape.
+func filePathFromHeader(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) >= 4 {
+		return strings.TrimPrefix(fields[3], "b/")
+	}
+	return line
+}
@@ -0,0 +1,51 @@
+package diffsplit
+
+import (
+	"strings"
+	"testing"
+)
+
+const twoFileDiff = `diff --git a/foo.go b/foo.go
+index 1111111..2222222 100644
+--- a/foo.go
++++ b/foo.go
+@@ -1,2 +1,2 @@
+-old foo
++new foo
+diff --git a/bar.go b/bar.go
+index 3333333..4444444 100644
+--- a/bar.go
++++ b/bar.go
+@@ -1,2 +1,2 @@
+-old bar
++new bar
+`
+
+func TestSplitByFile(t *testing.T) {
+	chunks := SplitByFile(twoFileDiff)
+
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	if chunks[0].Path != "foo.go" {
+		t.Errorf("got path %q, want %q", chunks[0].Path, "foo.go")
+	}
+	if chunks[1].Path != "bar.go" {
+		t.Errorf("got path %q, want %q", chunks[1].Path, "bar.go")
+	}
+}
+
+func TestSplitHunksPreservesFileHeader(t *testing.T) {
+	chunks := SplitByFile(twoFileDiff)
+	hunks := SplitHunks(chunks[0])
+
+	if len(hunks) != 1 {
+		t.Fatalf("got %d hunks, want 1", len(hunks))
+	}
+	if hunks[0].Path != "foo.go" {
+		t.Errorf("got path %q, want %q", hunks[0].Path, "foo.go")
+	}
+	if !strings.Contains(hunks[0].Body, "diff --git a/foo.go b/foo.go") || !strings.Contains(hunks[0].Body, "@@ -1,2 +1,2 @@") {
+		t.Errorf("expected hunk body to retain both the file and hunk headers, got:\n%s", hunks[0].Body)
+	}
+}
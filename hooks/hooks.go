@@ -0,0 +1,187 @@
+// Package hooks installs and removes the Git and Jujutsu integrations that
+// let lazycommit pre-populate a commit message automatically, instead of
+// being invoked by hand on every commit.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stayradiated/lazycommit/oscommands"
+)
+
+// ErrExists is returned by InstallGit when a prepare-commit-msg hook already
+// exists and neither Force nor Chain was given.
+var ErrExists = errors.New("a prepare-commit-msg hook already exists; pass --force to overwrite it or --chain to append to it")
+
+// ErrNotInstalled is returned by UninstallGit and UninstallJJ when no lazycommit hook is
+// present to remove.
+var ErrNotInstalled = errors.New("no lazycommit hook is installed")
+
+// Marker identifies lazycommit's own contribution to a hook file, so
+// InstallGit can detect a prior install and UninstallGit can remove just that
+// part of a chained hook.
+const Marker = "# Installed by `lazycommit install-hook`. Run `lazycommit uninstall-hook` to remove."
+
+// gitHookShebang is the line every standalone Git hook starts with.
+const gitHookShebang = "#!/bin/sh\n"
+
+// gitHookBody runs lazycommit to populate the commit message file, but
+// only when Git hasn't already been given one: $2 (the hook's "source"
+// argument) is non-empty for -m, -F, --amend, merges, and squashes, and
+// empty only when the user is about to see an empty message in $EDITOR.
+var gitHookBody = Marker + `
+COMMIT_MSG_FILE="$1"
+COMMIT_SOURCE="$2"
+
+if [ -n "$COMMIT_SOURCE" ] || [ -s "$COMMIT_MSG_FILE" ]; then
+  exit 0
+fi
+
+lazycommit --stdout-only > "$COMMIT_MSG_FILE"
+`
+
+// Options configures InstallGit, UninstallGit, InstallJJ and UninstallJJ.
+type Options struct {
+	// Global installs into the user's global Git hooks directory
+	// (core.hooksPath, or ~/.config/git/hooks if that's unset) instead of
+	// the current repository's .git/hooks.
+	Global bool
+
+	// Force overwrites an existing prepare-commit-msg hook that isn't
+	// already lazycommit's own.
+	Force bool
+
+	// Chain appends lazycommit's hook to the end of an existing
+	// prepare-commit-msg script instead of replacing it.
+	Chain bool
+
+	// Timeout bounds how long any jj invocation made while
+	// installing/uninstalling the Jujutsu integration is allowed to run.
+	Timeout time.Duration
+}
+
+// InstallGit writes (or, with Chain, appends) a prepare-commit-msg hook
+// that runs lazycommit. It returns the path the hook was written to.
+func InstallGit(opts Options) (string, error) {
+	path, err := gitHookPath(opts.Global)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case err != nil && !os.IsNotExist(err):
+		return "", fmt.Errorf("failed to read existing hook: %v", err)
+	case err == nil && strings.Contains(string(existing), Marker):
+		// Already lazycommit's hook; rewrite just its own block, preserving
+		// whatever precedes it (e.g. a chained-onto custom script).
+		idx := strings.Index(string(existing), Marker)
+		prefix := strings.TrimRight(string(existing[:idx]), "\n")
+		if prefix == "" || prefix == strings.TrimRight(gitHookShebang, "\n") {
+			return path, writeGitHook(path, gitHookShebang+gitHookBody)
+		}
+		return path, writeGitHook(path, prefix+"\n\n"+gitHookBody)
+	case err == nil && opts.Chain:
+		return path, writeGitHook(path, strings.TrimRight(string(existing), "\n")+"\n\n"+gitHookBody)
+	case err == nil && !opts.Force:
+		return "", ErrExists
+	}
+
+	return path, writeGitHook(path, gitHookShebang+gitHookBody)
+}
+
+// UninstallGit removes lazycommit's contribution to the prepare-commit-msg
+// hook: the whole file if lazycommit installed it standalone, or just its
+// appended block if it was chained onto an existing hook.
+func UninstallGit(opts Options) error {
+	path, err := gitHookPath(opts.Global)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotInstalled
+		}
+		return fmt.Errorf("failed to read hook: %v", err)
+	}
+
+	idx := strings.Index(string(content), Marker)
+	if idx == -1 {
+		return ErrNotInstalled
+	}
+
+	remainder := strings.TrimRight(string(content[:idx]), "\n")
+	if remainder == "" || remainder == strings.TrimRight(gitHookShebang, "\n") {
+		return os.Remove(path)
+	}
+
+	return writeGitHook(path, remainder+"\n")
+}
+
+// gitHookPath returns where the prepare-commit-msg hook belongs: the
+// repository-local .git/hooks, or the global hooks directory when global
+// is true (core.hooksPath if configured, else ~/.config/git/hooks).
+func gitHookPath(global bool) (string, error) {
+	if !global {
+		return filepath.Join(".git", "hooks", "prepare-commit-msg"), nil
+	}
+
+	hooksPath, err := oscommands.NewCmdBuilder("git").
+		AddOptions("config", "--global", "--get", "core.hooksPath").
+		RunWithContext(context.Background())
+	hooksDir := strings.TrimSpace(hooksPath)
+	if err != nil || hooksDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not determine home directory: %v", err)
+		}
+		hooksDir = filepath.Join(homeDir, ".config", "git", "hooks")
+	}
+
+	return filepath.Join(hooksDir, "prepare-commit-msg"), nil
+}
+
+// writeGitHook writes content to path as an executable file, creating its
+// parent directory (and, for a global install, setting core.hooksPath) as
+// needed.
+func writeGitHook(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		return fmt.Errorf("failed to write hook: %v", err)
+	}
+
+	return configureGlobalHooksPath(path)
+}
+
+// configureGlobalHooksPath points Git's core.hooksPath at path's directory
+// if it isn't already set to it, so a global hook actually runs.
+func configureGlobalHooksPath(path string) error {
+	hooksDir := filepath.Dir(path)
+	if hooksDir == filepath.Join(".git", "hooks") {
+		return nil
+	}
+
+	current, _ := oscommands.NewCmdBuilder("git").
+		AddOptions("config", "--global", "--get", "core.hooksPath").
+		RunWithContext(context.Background())
+	if strings.TrimSpace(current) == hooksDir {
+		return nil
+	}
+
+	_, err := oscommands.NewCmdBuilder("git").
+		AddOptions("config", "--global", "core.hooksPath").
+		AddDynamicArguments(hooksDir).
+		RunWithContext(context.Background())
+	return err
+}
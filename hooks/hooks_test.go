@@ -0,0 +1,163 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func withTempRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(oldwd) })
+	return dir
+}
+
+func TestInstallGitWritesHook(t *testing.T) {
+	withTempRepo(t)
+
+	path, err := InstallGit(Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != filepath.Join(".git", "hooks", "prepare-commit-msg") {
+		t.Errorf("got path %q", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("hook was not written: %v", err)
+	}
+	if !strings.Contains(string(content), Marker) {
+		t.Errorf("hook doesn't contain the lazycommit marker")
+	}
+}
+
+func TestInstallGitRefusesExistingHookWithoutForce(t *testing.T) {
+	withTempRepo(t)
+
+	path := filepath.Join(".git", "hooks", "prepare-commit-msg")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho custom\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := InstallGit(Options{}); err != ErrExists {
+		t.Fatalf("got error %v, want ErrExists", err)
+	}
+}
+
+func TestInstallGitChainsOntoExistingHook(t *testing.T) {
+	withTempRepo(t)
+
+	path := filepath.Join(".git", "hooks", "prepare-commit-msg")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	custom := "#!/bin/sh\necho custom\n"
+	if err := os.WriteFile(path, []byte(custom), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := InstallGit(Options{Chain: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "echo custom") || !strings.Contains(string(content), Marker) {
+		t.Errorf("expected chained hook to contain both scripts, got:\n%s", content)
+	}
+}
+
+func TestInstallGitReinstallPreservesChainedHook(t *testing.T) {
+	withTempRepo(t)
+
+	path := filepath.Join(".git", "hooks", "prepare-commit-msg")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho custom\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InstallGit(Options{Chain: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-running the installer with no special flags (e.g. after an
+	// upgrade) must not destroy the chained custom script.
+	if _, err := InstallGit(Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "echo custom") || !strings.Contains(string(content), Marker) {
+		t.Errorf("expected reinstall to preserve the chained script, got:\n%s", content)
+	}
+}
+
+func TestUninstallGitRemovesStandaloneHook(t *testing.T) {
+	withTempRepo(t)
+
+	path, err := InstallGit(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UninstallGit(Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected hook to be removed")
+	}
+}
+
+func TestUninstallGitKeepsChainedHook(t *testing.T) {
+	withTempRepo(t)
+
+	path := filepath.Join(".git", "hooks", "prepare-commit-msg")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho custom\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InstallGit(Options{Chain: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UninstallGit(Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected chained hook's original script to survive: %v", err)
+	}
+	if !strings.Contains(string(content), "echo custom") || strings.Contains(string(content), Marker) {
+		t.Errorf("expected only lazycommit's block to be removed, got:\n%s", content)
+	}
+}
+
+func TestUninstallGitReturnsErrNotInstalled(t *testing.T) {
+	withTempRepo(t)
+
+	if err := UninstallGit(Options{}); err != ErrNotInstalled {
+		t.Fatalf("got error %v, want ErrNotInstalled", err)
+	}
+}
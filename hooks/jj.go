@@ -0,0 +1,62 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stayradiated/lazycommit/oscommands"
+)
+
+// jjAlias is the `jj <name>` alias lazycommit installs. Jujutsu has no
+// prepare-commit-msg equivalent to hook into directly, so instead of
+// patching `jj describe` itself, lazycommit adds a sibling command that
+// generates a message and describes the working copy with it in one step.
+const jjAlias = "describe-ai"
+
+// jjAliasValue is the `jj util exec` invocation the alias expands to: run
+// lazycommit in stdout-only mode and feed its output straight to
+// `jj describe -m`.
+const jjAliasValue = `["util", "exec", "--", "sh", "-c", "jj describe -m \"$(lazycommit --stdout-only)\""]`
+
+// InstallJJ registers the describe-ai alias via `jj config set`, scoped to
+// the repository unless Global is set.
+func InstallJJ(opts Options) error {
+	_, err := jjConfigCmd(opts).
+		AddOptions("set", jjConfigScope(opts.Global), "aliases."+jjAlias).
+		AddDynamicArguments(jjAliasValue).
+		RunWithContext(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to set jj alias: %v", err)
+	}
+	return nil
+}
+
+// UninstallJJ removes the describe-ai alias, if present.
+func UninstallJJ(opts Options) error {
+	existing, err := jjConfigCmd(opts).
+		AddOptions("get", "aliases."+jjAlias).
+		RunWithContext(context.Background())
+	if err != nil || strings.TrimSpace(existing) == "" {
+		return ErrNotInstalled
+	}
+
+	_, err = jjConfigCmd(opts).
+		AddOptions("unset", jjConfigScope(opts.Global), "aliases."+jjAlias).
+		RunWithContext(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to unset jj alias: %v", err)
+	}
+	return nil
+}
+
+func jjConfigCmd(opts Options) *oscommands.CmdBuilder {
+	return oscommands.NewCmdBuilder("jj").AddOptions("config").WithTimeout(opts.Timeout)
+}
+
+func jjConfigScope(global bool) string {
+	if global {
+		return "--user"
+	}
+	return "--repo"
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// anthropicVersion is the API version sent with every request, per
+// Anthropic's versioning scheme.
+const anthropicVersion = "2023-06-01"
+
+// anthropicMaxTokens bounds the length of a generated commit message. The
+// Messages API requires max_tokens on every request; commit messages are
+// short, so a generous fixed budget is simpler than threading a config
+// option through for it.
+const anthropicMaxTokens = 4096
+
+// AnthropicProvider talks to Anthropic's Messages API directly. Unlike
+// OpenAICompatibleProvider it is not OpenAI-shaped: it posts to
+// baseURL+"/messages", sends the system prompt as a top-level field,
+// authenticates with an x-api-key header instead of a bearer token, and
+// reads back content blocks rather than choices.
+type AnthropicProvider struct {
+	cfg     Config
+	baseURL string
+	client  *http.Client
+}
+
+// NewAnthropicProvider creates a provider that posts to
+// baseURL+"/messages".
+func NewAnthropicProvider(cfg Config, baseURL string) *AnthropicProvider {
+	return &AnthropicProvider{
+		cfg:     cfg,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	MaxTokens int                `json:"max_tokens"`
+	Stream    bool               `json:"stream"`
+}
+
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+}
+
+// Complete posts a streaming Messages API request and returns the
+// assembled response text.
+func (p *AnthropicProvider) Complete(ctx context.Context, system, user string) (string, error) {
+	if p.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.Timeout)
+		defer cancel()
+	}
+
+	body, err := json.Marshal(anthropicRequest{
+		Model:  p.cfg.Model,
+		System: system,
+		Messages: []anthropicMessage{
+			{Role: "user", Content: user},
+		},
+		MaxTokens: anthropicMaxTokens,
+		Stream:    true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if p.cfg.APIKeyEnv != "" {
+		if key := os.Getenv(p.cfg.APIKeyEnv); key != "" {
+			req.Header.Set("x-api-key", key)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request to %s failed: %v", p.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s", p.baseURL, resp.Status)
+	}
+
+	return p.readStream(resp.Body)
+}
+
+// readStream reads a text/event-stream response body, extracting and
+// accumulating each content_block_delta's text, and writing it to
+// cfg.Stream as it arrives if one was configured.
+func (p *AnthropicProvider) readStream(body io.Reader) (string, error) {
+	var out strings.Builder
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return "", fmt.Errorf("failed to decode stream event: %v", err)
+		}
+		if event.Type != "content_block_delta" || event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+			continue
+		}
+
+		out.WriteString(event.Delta.Text)
+		if p.cfg.Stream != nil {
+			fmt.Fprint(p.cfg.Stream, event.Delta.Text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read response stream: %v", err)
+	}
+
+	return out.String(), nil
+}
@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleAnthropicStream = `event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Add "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"feature"}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+func TestAnthropicReadStreamAssemblesDeltas(t *testing.T) {
+	p := &AnthropicProvider{}
+
+	got, err := p.readStream(strings.NewReader(sampleAnthropicStream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Add feature" {
+		t.Errorf("got %q, want %q", got, "Add feature")
+	}
+}
+
+func TestAnthropicReadStreamWritesToConfiguredStream(t *testing.T) {
+	var buf strings.Builder
+	p := &AnthropicProvider{cfg: Config{Stream: &buf}}
+
+	if _, err := p.readStream(strings.NewReader(sampleAnthropicStream)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "Add feature" {
+		t.Errorf("got %q written to stream, want %q", buf.String(), "Add feature")
+	}
+}
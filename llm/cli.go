@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/stayradiated/lazycommit/oscommands"
+)
+
+// CLIProvider shells out to the external `llm` command. It is LazyCommit's
+// original behavior and the default provider.
+type CLIProvider struct {
+	cfg Config
+}
+
+// NewCLIProvider creates a CLIProvider.
+func NewCLIProvider(cfg Config) *CLIProvider {
+	return &CLIProvider{cfg: cfg}
+}
+
+// Complete runs `llm --system=<system>`, piping user in as stdin. The
+// system prompt is passed as a single "--system=value" argv entry rather
+// than "-s value" so that free-form prompt text starting with "-" (a
+// common bullet-point convention) can never be parsed as a separate flag.
+func (p *CLIProvider) Complete(ctx context.Context, system, user string) (string, error) {
+	cmd := oscommands.NewCmdBuilder("llm").WithTimeout(p.cfg.Timeout)
+	if p.cfg.Model != "" {
+		cmd.AddOptions("-m").AddDynamicArguments(p.cfg.Model)
+	}
+	cmd.AddFreeformArgument("--system", system)
+
+	var buf bytes.Buffer
+	var stdout io.Writer = &buf
+	if p.cfg.Stream != nil {
+		stdout = io.MultiWriter(&buf, p.cfg.Stream)
+	}
+
+	if err := cmd.RunWithContextStreaming(ctx, strings.NewReader(user), stdout, os.Stderr); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,83 @@
+// Package llm provides LazyCommit's LLM provider abstraction: a small
+// Provider interface with a CLI adapter (the original behavior, shelling
+// out to the `llm` command) and native adapters — one for Anthropic's
+// Messages API, one for OpenAI-compatible HTTP APIs — so a user can
+// configure an API key directly instead of installing the external `llm`
+// binary.
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Provider generates a single chat completion from a system and user
+// prompt.
+type Provider interface {
+	// Complete sends system and user to the provider and returns its full
+	// response. If Config.Stream was set, the response is also written
+	// there incrementally as it arrives.
+	Complete(ctx context.Context, system, user string) (string, error)
+}
+
+// Config selects and configures a Provider.
+type Config struct {
+	// Name is the provider to use: "cli" (default), "openai", "anthropic",
+	// "ollama", "openrouter", or "llamacpp".
+	Name string
+
+	// Model is the model name to request. Required for every provider
+	// except "cli", where an empty Model lets `llm` pick its own default.
+	Model string
+
+	// BaseURL overrides the provider's default API base URL.
+	BaseURL string
+
+	// APIKeyEnv is the name of the environment variable holding the API
+	// key: sent as a bearer token by the OpenAI-compatible providers and
+	// in an x-api-key header by "anthropic". Unused by "cli".
+	APIKeyEnv string
+
+	// Timeout bounds how long a single completion request is allowed to
+	// take. Zero disables the deadline.
+	Timeout time.Duration
+
+	// Stream, if set, receives the response incrementally as it arrives,
+	// so output can reach the terminal before the full response lands.
+	Stream io.Writer
+}
+
+// defaultBaseURLs holds the default API base URL for each built-in native
+// provider: the OpenAI-compatible ones and "anthropic".
+var defaultBaseURLs = map[string]string{
+	"openai":     "https://api.openai.com/v1",
+	"anthropic":  "https://api.anthropic.com/v1",
+	"ollama":     "http://localhost:11434/v1",
+	"openrouter": "https://openrouter.ai/api/v1",
+	"llamacpp":   "http://localhost:8080/v1",
+}
+
+// New constructs the Provider selected by cfg.Name.
+func New(cfg Config) (Provider, error) {
+	switch cfg.Name {
+	case "", "cli":
+		return NewCLIProvider(cfg), nil
+	case "anthropic":
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = defaultBaseURLs[cfg.Name]
+		}
+		return NewAnthropicProvider(cfg, strings.TrimSuffix(baseURL, "/")), nil
+	case "openai", "ollama", "openrouter", "llamacpp":
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = defaultBaseURLs[cfg.Name]
+		}
+		return NewOpenAICompatibleProvider(cfg, strings.TrimSuffix(baseURL, "/")), nil
+	default:
+		return nil, fmt.Errorf("unknown llm provider %q", cfg.Name)
+	}
+}
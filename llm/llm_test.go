@@ -0,0 +1,58 @@
+package llm
+
+import "testing"
+
+func TestNewDefaultsToCLIProvider(t *testing.T) {
+	p, err := New(Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := p.(*CLIProvider); !ok {
+		t.Errorf("expected a *CLIProvider, got %T", p)
+	}
+}
+
+func TestNewRejectsUnknownProvider(t *testing.T) {
+	if _, err := New(Config{Name: "bogus"}); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+}
+
+func TestNewOpenAICompatibleUsesDefaultBaseURL(t *testing.T) {
+	p, err := New(Config{Name: "ollama"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oc, ok := p.(*OpenAICompatibleProvider)
+	if !ok {
+		t.Fatalf("expected a *OpenAICompatibleProvider, got %T", p)
+	}
+	if oc.baseURL != defaultBaseURLs["ollama"] {
+		t.Errorf("got base URL %q, want %q", oc.baseURL, defaultBaseURLs["ollama"])
+	}
+}
+
+func TestNewAnthropicUsesDefaultBaseURL(t *testing.T) {
+	p, err := New(Config{Name: "anthropic"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ap, ok := p.(*AnthropicProvider)
+	if !ok {
+		t.Fatalf("expected a *AnthropicProvider, got %T", p)
+	}
+	if ap.baseURL != defaultBaseURLs["anthropic"] {
+		t.Errorf("got base URL %q, want %q", ap.baseURL, defaultBaseURLs["anthropic"])
+	}
+}
+
+func TestNewOpenAICompatibleHonorsBaseURLOverride(t *testing.T) {
+	p, err := New(Config{Name: "openai", BaseURL: "http://localhost:9999/v1/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oc := p.(*OpenAICompatibleProvider)
+	if oc.baseURL != "http://localhost:9999/v1" {
+		t.Errorf("got base URL %q, want trailing slash trimmed", oc.baseURL)
+	}
+}
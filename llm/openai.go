@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// OpenAICompatibleProvider talks to any OpenAI-compatible "/chat/completions"
+// HTTP endpoint: OpenAI, Ollama, OpenRouter, and local llama.cpp servers all
+// expose (or can be proxied to expose) this shape. Anthropic has its own
+// Messages API and is handled by AnthropicProvider instead.
+type OpenAICompatibleProvider struct {
+	cfg     Config
+	baseURL string
+	client  *http.Client
+}
+
+// NewOpenAICompatibleProvider creates a provider that posts to
+// baseURL+"/chat/completions".
+func NewOpenAICompatibleProvider(cfg Config, baseURL string) *OpenAICompatibleProvider {
+	return &OpenAICompatibleProvider{
+		cfg:     cfg,
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+type chatStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// Complete posts a streaming chat-completion request and returns the
+// assembled response text.
+func (p *OpenAICompatibleProvider) Complete(ctx context.Context, system, user string) (string, error) {
+	if p.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.Timeout)
+		defer cancel()
+	}
+
+	body, err := json.Marshal(chatRequest{
+		Model: p.cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		Stream: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.cfg.APIKeyEnv != "" {
+		if key := os.Getenv(p.cfg.APIKeyEnv); key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request to %s failed: %v", p.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s", p.baseURL, resp.Status)
+	}
+
+	return p.readStream(resp.Body)
+}
+
+// readStream reads a text/event-stream response body, extracting and
+// accumulating each chunk's delta content, and writing it to cfg.Stream as
+// it arrives if one was configured.
+func (p *OpenAICompatibleProvider) readStream(body io.Reader) (string, error) {
+	var out strings.Builder
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk chatStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("failed to decode stream chunk: %v", err)
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		if delta == "" {
+			continue
+		}
+
+		out.WriteString(delta)
+		if p.cfg.Stream != nil {
+			fmt.Fprint(p.cfg.Stream, delta)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read response stream: %v", err)
+	}
+
+	return out.String(), nil
+}
@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleStream = `data: {"choices":[{"delta":{"content":"Add "}}]}
+data: {"choices":[{"delta":{"content":"feature"}}]}
+data: {"choices":[{"delta":{}}]}
+data: [DONE]
+`
+
+func TestReadStreamAssemblesDeltas(t *testing.T) {
+	p := &OpenAICompatibleProvider{}
+
+	got, err := p.readStream(strings.NewReader(sampleStream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Add feature" {
+		t.Errorf("got %q, want %q", got, "Add feature")
+	}
+}
+
+func TestReadStreamWritesToConfiguredStream(t *testing.T) {
+	var buf strings.Builder
+	p := &OpenAICompatibleProvider{cfg: Config{Stream: &buf}}
+
+	if _, err := p.readStream(strings.NewReader(sampleStream)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "Add feature" {
+		t.Errorf("got %q written to stream, want %q", buf.String(), "Add feature")
+	}
+}
@@ -0,0 +1,158 @@
+// Package oscommands provides a safer, argv-based way to build and run
+// external commands than calling exec.Command directly at each call site.
+// It is modeled on Gitea's git-module command builder: trusted, static
+// arguments go through AddOptions, while anything derived from user input
+// (branch names, template paths, revsets) must go through
+// AddDynamicArguments, which refuses values that look like flags, or, for
+// free-form text such as a rendered system prompt, through
+// AddFreeformArgument, which binds the value into a single flag=value entry.
+package oscommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrTimeout is returned, wrapped with the offending command, when a
+// command exceeds its configured timeout.
+var ErrTimeout = errors.New("command timed out")
+
+// CmdBuilder builds the argv for a single command.
+type CmdBuilder struct {
+	name    string
+	args    []string
+	timeout time.Duration
+	err     error
+}
+
+// NewCmdBuilder starts building a command invoking name.
+func NewCmdBuilder(name string) *CmdBuilder {
+	return &CmdBuilder{name: name}
+}
+
+// AddOptions appends trusted, static arguments: flags and subcommands that
+// are hard-coded in this codebase, never derived from user input.
+func (b *CmdBuilder) AddOptions(args ...string) *CmdBuilder {
+	b.args = append(b.args, args...)
+	return b
+}
+
+// AddDynamicArguments appends user-derived values: branch names, revsets,
+// file paths, template output. Each value is checked against being
+// misinterpreted as a flag by the target command; a value starting with
+// "-" is rejected rather than silently passed through.
+func (b *CmdBuilder) AddDynamicArguments(values ...string) *CmdBuilder {
+	for _, v := range values {
+		if strings.HasPrefix(v, "-") {
+			if b.err == nil {
+				b.err = fmt.Errorf("refusing to pass %q as an argument to %s: looks like a flag", v, b.name)
+			}
+			continue
+		}
+		b.args = append(b.args, v)
+	}
+	return b
+}
+
+// AddFreeformArgument appends a long-form "flag=value" argument as a
+// single argv entry, with no check against value looking like a flag. It
+// exists for free-form, multi-word text such as system prompts, which
+// would false-positive on the AddDynamicArguments check (a prompt written
+// as a bulleted list commonly starts with "-") even though it isn't
+// actually ambiguous with a flag: embedding it in flag's own token means
+// it can never be parsed as a separate argv entry by the target command,
+// unlike a bare positional value passed via AddDynamicArguments.
+func (b *CmdBuilder) AddFreeformArgument(flag, value string) *CmdBuilder {
+	b.args = append(b.args, flag+"="+value)
+	return b
+}
+
+// WithTimeout bounds how long RunWithContext and RunWithContextStreaming
+// are allowed to take, on top of any deadline already on the context they
+// are given.
+func (b *CmdBuilder) WithTimeout(timeout time.Duration) *CmdBuilder {
+	b.timeout = timeout
+	return b
+}
+
+// RunWithContext executes the command and returns its standard output.
+func (b *CmdBuilder) RunWithContext(ctx context.Context) (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, b.name, b.args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", b.wrapErr(ctx, err)
+	}
+
+	return string(output), nil
+}
+
+// RunWithStdin executes the command with stdin wired up and returns its
+// standard output, for commands that read their input from a pipe but whose
+// result should still be captured rather than streamed to the terminal.
+func (b *CmdBuilder) RunWithStdin(ctx context.Context, stdin io.Reader) (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, b.name, b.args...)
+	cmd.Stdin = stdin
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", b.wrapErr(ctx, err)
+	}
+
+	return string(output), nil
+}
+
+// RunWithContextStreaming executes the command with stdin/stdout/stderr
+// wired up directly, for commands whose output should reach the terminal
+// as it's produced rather than being captured.
+func (b *CmdBuilder) RunWithContextStreaming(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) error {
+	if b.err != nil {
+		return b.err
+	}
+
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, b.name, b.args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return b.wrapErr(ctx, err)
+	}
+
+	return nil
+}
+
+func (b *CmdBuilder) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, b.timeout)
+}
+
+func (b *CmdBuilder) wrapErr(ctx context.Context, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s %s: %w", b.name, strings.Join(b.args, " "), ErrTimeout)
+	}
+	return fmt.Errorf("%s %s: %w", b.name, strings.Join(b.args, " "), err)
+}
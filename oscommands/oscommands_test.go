@@ -0,0 +1,47 @@
+package oscommands
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAddDynamicArgumentsRejectsFlagLikeValues(t *testing.T) {
+	b := NewCmdBuilder("echo").AddOptions("-n").AddDynamicArguments("--evil")
+
+	if _, err := b.RunWithContext(context.Background()); err == nil {
+		t.Fatal("expected an error for a flag-like dynamic argument")
+	}
+}
+
+func TestAddFreeformArgumentAllowsFlagLikeValues(t *testing.T) {
+	b := NewCmdBuilder("echo").AddFreeformArgument("--system", "- bullet point")
+
+	out, err := b.RunWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "--system=- bullet point\n" {
+		t.Errorf("got %q, want %q", out, "--system=- bullet point\n")
+	}
+}
+
+func TestRunWithContextReturnsOutput(t *testing.T) {
+	b := NewCmdBuilder("echo").AddDynamicArguments("hello")
+
+	out, err := b.RunWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "hello\n" {
+		t.Errorf("got %q, want %q", out, "hello\n")
+	}
+}
+
+func TestRunWithContextHonorsTimeout(t *testing.T) {
+	b := NewCmdBuilder("sleep").AddDynamicArguments("1").WithTimeout(10 * time.Millisecond)
+
+	if _, err := b.RunWithContext(context.Background()); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+}
@@ -0,0 +1,228 @@
+// Package templates implements LazyCommit's named prompt template library:
+// loading templates from the global config directory and from a per-repo
+// ".lazycommit" directory, merging the two scopes, and rendering a template
+// against the variables supplied on the command line.
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Variable describes one named value that a template accepts, along with how
+// to validate it.
+type Variable struct {
+	Name        string `toml:"name"`
+	Description string `toml:"description"`
+	Default     string `toml:"default"`
+	Required    bool   `toml:"required"`
+	Pattern     string `toml:"pattern"`
+}
+
+// Template is a single named prompt template.
+type Template struct {
+	Name        string     `toml:"name"`
+	Description string     `toml:"description"`
+	System      string     `toml:"system"`
+	Variables   []Variable `toml:"variables"`
+}
+
+// file is the on-disk shape of a templates.toml file: either a single
+// template or an array of templates under the "template" key.
+type file struct {
+	Template
+	Templates []Template `toml:"template"`
+}
+
+// Library is a named collection of templates, keyed by Template.Name.
+type Library struct {
+	Templates map[string]Template
+}
+
+// Data is the context exposed to a template's text/template execution.
+type Data struct {
+	Branch      string
+	UserContext string
+	Vars        map[string]string
+}
+
+// RenderData builds the Data passed to text/template, filling in defaults
+// and checking required variables for the given template.
+func (t Template) RenderData(branch, userContext string, vars map[string]string) (Data, error) {
+	resolved, err := t.resolveVariables(vars)
+	if err != nil {
+		return Data{}, err
+	}
+
+	return Data{
+		Branch:      branch,
+		UserContext: userContext,
+		Vars:        resolved,
+	}, nil
+}
+
+// resolveVariables fills in defaults, enforces required variables, and
+// validates supplied values against each variable's pattern.
+func (t Template) resolveVariables(vars map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(t.Variables))
+
+	for _, v := range t.Variables {
+		value, ok := vars[v.Name]
+		if !ok || value == "" {
+			if v.Required && v.Default == "" {
+				return nil, fmt.Errorf("template %q: missing required variable %q", t.Name, v.Name)
+			}
+			value = v.Default
+		}
+
+		if v.Pattern != "" && value != "" {
+			re, err := regexp.Compile(v.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("template %q: invalid pattern for variable %q: %v", t.Name, v.Name, err)
+			}
+			if !re.MatchString(value) {
+				return nil, fmt.Errorf("template %q: variable %q value %q does not match pattern %q", t.Name, v.Name, value, v.Pattern)
+			}
+		}
+
+		resolved[v.Name] = value
+	}
+
+	// Pass through any extra variables that weren't declared, so ad-hoc
+	// -v flags still reach the template.
+	for k, v := range vars {
+		if _, declared := resolved[k]; !declared {
+			resolved[k] = v
+		}
+	}
+
+	return resolved, nil
+}
+
+// Render parses and executes the template's System prompt against data.
+func (t Template) Render(data Data) (string, error) {
+	tmpl, err := template.New(t.Name).Parse(t.System)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %q: %v", t.Name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template %q: %v", t.Name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// Get looks up a template by name.
+func (l Library) Get(name string) (Template, bool) {
+	t, ok := l.Templates[name]
+	return t, ok
+}
+
+// Names returns the template names in the library, sorted alphabetically.
+func (l Library) Names() []string {
+	names := make([]string, 0, len(l.Templates))
+	for name := range l.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Merge combines the global and repo-scoped libraries, with repo templates
+// taking precedence over global templates of the same name.
+func Merge(global, repo Library) Library {
+	merged := Library{Templates: make(map[string]Template, len(global.Templates)+len(repo.Templates))}
+
+	for name, t := range global.Templates {
+		merged.Templates[name] = t
+	}
+	for name, t := range repo.Templates {
+		merged.Templates[name] = t
+	}
+
+	return merged
+}
+
+// LoadDir loads every *.toml file in dir as a templates file and returns the
+// combined library. A missing directory is not an error; it yields an empty
+// library.
+func LoadDir(dir string) (Library, error) {
+	lib := Library{Templates: map[string]Template{}}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lib, nil
+		}
+		return lib, fmt.Errorf("failed to read templates directory %s: %v", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err := loadFileInto(lib, path); err != nil {
+			return lib, err
+		}
+	}
+
+	return lib, nil
+}
+
+// LoadGlobal loads the global template library from
+// "<configDir>/templates/*.toml".
+func LoadGlobal(configDir string) (Library, error) {
+	return LoadDir(filepath.Join(configDir, "templates"))
+}
+
+// LoadRepo loads the per-repo template library from
+// "<repoRoot>/.lazycommit/templates.toml", falling back to an empty library
+// if the file does not exist.
+func LoadRepo(repoRoot string) (Library, error) {
+	lib := Library{Templates: map[string]Template{}}
+
+	path := filepath.Join(repoRoot, ".lazycommit", "templates.toml")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return lib, nil
+	}
+
+	if err := loadFileInto(lib, path); err != nil {
+		return lib, err
+	}
+
+	return lib, nil
+}
+
+// loadFileInto decodes a single templates.toml file and merges its templates
+// into lib.
+func loadFileInto(lib Library, path string) error {
+	var f file
+	if _, err := toml.DecodeFile(path, &f); err != nil {
+		return fmt.Errorf("failed to load templates from %s: %v", path, err)
+	}
+
+	all := f.Templates
+	if f.Template.Name != "" {
+		all = append(all, f.Template)
+	}
+
+	for _, t := range all {
+		if t.Name == "" {
+			return fmt.Errorf("templates file %s: template missing required \"name\" field", path)
+		}
+		lib.Templates[t.Name] = t
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package templates
+
+import "testing"
+
+func TestMergeRepoWinsOverGlobal(t *testing.T) {
+	global := Library{Templates: map[string]Template{
+		"default": {Name: "default", System: "global system"},
+		"only-global": {Name: "only-global", System: "global only"},
+	}}
+	repo := Library{Templates: map[string]Template{
+		"default": {Name: "default", System: "repo system"},
+	}}
+
+	merged := Merge(global, repo)
+
+	got, ok := merged.Get("default")
+	if !ok {
+		t.Fatalf("expected merged library to contain %q", "default")
+	}
+	if got.System != "repo system" {
+		t.Errorf("expected repo template to win, got system %q", got.System)
+	}
+
+	if _, ok := merged.Get("only-global"); !ok {
+		t.Errorf("expected global-only template to survive the merge")
+	}
+}
+
+func TestResolveVariablesAppliesDefaults(t *testing.T) {
+	tmpl := Template{
+		Name: "test",
+		Variables: []Variable{
+			{Name: "scope", Default: "api"},
+		},
+	}
+
+	resolved, err := tmpl.resolveVariables(map[string]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved["scope"] != "api" {
+		t.Errorf("expected default to be applied, got %q", resolved["scope"])
+	}
+}
+
+func TestResolveVariablesRequiresValue(t *testing.T) {
+	tmpl := Template{
+		Name: "test",
+		Variables: []Variable{
+			{Name: "ticket", Required: true},
+		},
+	}
+
+	if _, err := tmpl.resolveVariables(map[string]string{}); err == nil {
+		t.Fatal("expected error for missing required variable")
+	}
+
+	resolved, err := tmpl.resolveVariables(map[string]string{"ticket": "PROJ-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved["ticket"] != "PROJ-1" {
+		t.Errorf("expected supplied value to be used, got %q", resolved["ticket"])
+	}
+}
+
+func TestResolveVariablesValidatesPattern(t *testing.T) {
+	tmpl := Template{
+		Name: "test",
+		Variables: []Variable{
+			{Name: "ticket", Pattern: `^PROJ-\d+$`},
+		},
+	}
+
+	if _, err := tmpl.resolveVariables(map[string]string{"ticket": "not-a-ticket"}); err == nil {
+		t.Fatal("expected error for value not matching pattern")
+	}
+
+	if _, err := tmpl.resolveVariables(map[string]string{"ticket": "PROJ-42"}); err != nil {
+		t.Errorf("unexpected error for value matching pattern: %v", err)
+	}
+}
+
+func TestRenderExecutesSystemPrompt(t *testing.T) {
+	tmpl := Template{Name: "test", System: "Branch: {{.Branch}} Scope: {{.Vars.scope}}"}
+
+	out, err := tmpl.Render(Data{Branch: "main", Vars: map[string]string{"scope": "api"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "Branch: main Scope: api"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,76 @@
+// Package git implements the vcs.Backend interface for Git.
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/stayradiated/lazycommit/oscommands"
+	"github.com/stayradiated/lazycommit/vcs"
+)
+
+// Backend is the Git implementation of vcs.Backend.
+type Backend struct {
+	// Range is an optional "A..B" commit range. When set, Diff summarizes
+	// the commits in that range instead of the staged diff.
+	Range string
+
+	// Timeout bounds how long any single git invocation is allowed to run.
+	Timeout time.Duration
+}
+
+// New creates a Git backend. rangeArg is the --range value, or "" to diff
+// the staged changes. A timeout of 0 disables the per-command deadline.
+func New(rangeArg string, timeout time.Duration) *Backend {
+	return &Backend{Range: rangeArg, Timeout: timeout}
+}
+
+// Detect reports whether the current directory is inside a Git work tree.
+func (b *Backend) Detect() bool {
+	if _, err := os.Stat(".git"); err == nil {
+		return true
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	return cmd.Run() == nil
+}
+
+// ExcludeArg renders pattern as a Git pathspec exclusion.
+func (b *Backend) ExcludeArg(pattern string) string {
+	return fmt.Sprintf(":(exclude)%s", pattern)
+}
+
+// Diff returns the staged diff, or the diff across Range if one was
+// configured, excluding opts.ExcludePatterns.
+func (b *Backend) Diff(ctx context.Context, opts vcs.DiffOptions) (string, error) {
+	cmd := oscommands.NewCmdBuilder("git").WithTimeout(b.Timeout)
+
+	if b.Range != "" {
+		cmd.AddOptions("diff").AddDynamicArguments(b.Range).AddOptions("--", ".")
+	} else {
+		cmd.AddOptions("diff", "--cached", "--", ".")
+	}
+
+	for _, pattern := range opts.ExcludePatterns {
+		cmd.AddDynamicArguments(b.ExcludeArg(pattern))
+	}
+
+	return cmd.RunWithContext(ctx)
+}
+
+// BranchOrChange returns the current branch name.
+func (b *Backend) BranchOrChange(ctx context.Context) (string, error) {
+	output, err := oscommands.NewCmdBuilder("git").
+		AddOptions("rev-parse", "--abbrev-ref", "HEAD").
+		WithTimeout(b.Timeout).
+		RunWithContext(ctx)
+	if err != nil {
+		return "", nil
+	}
+
+	return strings.TrimSpace(output), nil
+}
@@ -0,0 +1,12 @@
+package git
+
+import "testing"
+
+func TestExcludeArg(t *testing.T) {
+	b := New("", 0)
+	got := b.ExcludeArg("pnpm-lock.yaml")
+	want := ":(exclude)pnpm-lock.yaml"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
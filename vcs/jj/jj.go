@@ -0,0 +1,76 @@
+// Package jj implements the vcs.Backend interface for Jujutsu.
+package jj
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/stayradiated/lazycommit/oscommands"
+	"github.com/stayradiated/lazycommit/vcs"
+)
+
+// DefaultRevset is used when no --revset/-r flag is given.
+const DefaultRevset = "@"
+
+// Backend is the Jujutsu implementation of vcs.Backend.
+type Backend struct {
+	// Revset selects the change(s) to operate on, e.g. "@", "@-", or
+	// "mine() & ~empty()".
+	Revset string
+
+	// Timeout bounds how long any single jj invocation is allowed to run.
+	Timeout time.Duration
+}
+
+// New creates a Jujutsu backend scoped to revset. An empty revset falls
+// back to DefaultRevset. A timeout of 0 disables the per-command deadline.
+func New(revset string, timeout time.Duration) *Backend {
+	if revset == "" {
+		revset = DefaultRevset
+	}
+	return &Backend{Revset: revset, Timeout: timeout}
+}
+
+// Detect reports whether the current directory is inside a Jujutsu repo.
+func (b *Backend) Detect() bool {
+	cmd := exec.Command("jj", "status", "--quiet")
+	return cmd.Run() == nil
+}
+
+// ExcludeArg renders pattern as a Jujutsu fileset exclusion.
+func (b *Backend) ExcludeArg(pattern string) string {
+	return fmt.Sprintf("~%s", pattern)
+}
+
+// Diff returns the diff for Revset, excluding opts.ExcludePatterns.
+func (b *Backend) Diff(ctx context.Context, opts vcs.DiffOptions) (string, error) {
+	cmd := oscommands.NewCmdBuilder("jj").
+		AddOptions("diff", "--git", "-r").
+		AddDynamicArguments(b.Revset).
+		WithTimeout(b.Timeout)
+
+	for _, pattern := range opts.ExcludePatterns {
+		cmd.AddDynamicArguments(b.ExcludeArg(pattern))
+	}
+
+	return cmd.RunWithContext(ctx)
+}
+
+// BranchOrChange returns the local bookmark (Jujutsu's equivalent of a
+// branch) pointing at Revset, if any.
+func (b *Backend) BranchOrChange(ctx context.Context) (string, error) {
+	output, err := oscommands.NewCmdBuilder("jj").
+		AddOptions("log", "--no-graph", "-r").
+		AddDynamicArguments(b.Revset).
+		AddOptions("-T", "local_bookmarks", "--limit", "1").
+		WithTimeout(b.Timeout).
+		RunWithContext(ctx)
+	if err != nil {
+		return "", nil
+	}
+
+	return strings.TrimSpace(output), nil
+}
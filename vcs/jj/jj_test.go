@@ -0,0 +1,24 @@
+package jj
+
+import "testing"
+
+func TestNewDefaultsRevset(t *testing.T) {
+	b := New("", 0)
+	if b.Revset != DefaultRevset {
+		t.Errorf("got revset %q, want default %q", b.Revset, DefaultRevset)
+	}
+
+	b = New("@-", 0)
+	if b.Revset != "@-" {
+		t.Errorf("got revset %q, want %q", b.Revset, "@-")
+	}
+}
+
+func TestExcludeArg(t *testing.T) {
+	b := New("", 0)
+	got := b.ExcludeArg("pnpm-lock.yaml")
+	want := "~pnpm-lock.yaml"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+// Package vcs defines the version-control backend abstraction that
+// LazyCommit uses to get a diff and identify the current unit of work,
+// independent of whether the repository is Git or Jujutsu.
+package vcs
+
+import "context"
+
+// DiffOptions configures a Diff call. Fields are common to every backend;
+// backend-specific settings (a Jujutsu revset, a Git commit range) are
+// configured when the Backend is constructed instead, since they're chosen
+// once per run rather than per call.
+type DiffOptions struct {
+	// ExcludePatterns are plain path patterns to exclude from the diff, e.g.
+	// generated files and lock files. Each backend's Diff renders every
+	// pattern in its own syntax with its ExcludeArg.
+	ExcludePatterns []string
+}
+
+// Backend is a version-control system that LazyCommit can read a diff from.
+type Backend interface {
+	// Detect reports whether the current directory is a repository of this
+	// backend's kind.
+	Detect() bool
+
+	// Diff returns the diff to summarize, according to opts and whatever
+	// backend-specific scope (range, revset) the Backend was constructed
+	// with.
+	Diff(ctx context.Context, opts DiffOptions) (string, error)
+
+	// BranchOrChange returns a human-readable identifier for the current
+	// unit of work: a branch name for Git, a local bookmark name for
+	// Jujutsu. It returns an empty string, not an error, when no such
+	// identifier is available.
+	BranchOrChange(ctx context.Context) (string, error)
+
+	// ExcludeArg renders a path pattern as this backend's native
+	// diff-exclusion argument.
+	ExcludeArg(pattern string) string
+}